@@ -0,0 +1,183 @@
+package ui
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/boba/textinput"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sahilm/fuzzy"
+)
+
+const (
+	headingPickerPromptText = " go to heading: "
+	headingPickerMaxResults = 8
+)
+
+var (
+	headingRe = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	fenceRe   = regexp.MustCompile("^(```|~~~)")
+)
+
+// heading is a single Markdown heading found in a document's raw body.
+type heading struct {
+	level int
+	text  string
+	line  int // 0-based line number in the source body
+}
+
+// parseHeadings scans raw markdown for ATX-style headings (# through
+// ######), skipping anything inside fenced code blocks so a commented-out
+// "#" doesn't get mistaken for a heading.
+func parseHeadings(body string) []heading {
+	var headings []heading
+
+	inFence := false
+	for i, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if fenceRe.MatchString(trimmed) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		if match := headingRe.FindStringSubmatch(line); match != nil {
+			headings = append(headings, heading{
+				level: len(match[1]),
+				text:  strings.TrimSpace(match[2]),
+				line:  i,
+			})
+		}
+	}
+
+	return headings
+}
+
+// headingPickerModel is the fuzzy-searchable list of headings shown when
+// jumping to a heading in the current document.
+type headingPickerModel struct {
+	input    textinput.Model
+	headings []heading
+	matches  fuzzy.Matches
+	selected int
+	theme    PagerTheme
+}
+
+func newHeadingPickerModel(theme PagerTheme, headings []heading) headingPickerModel {
+	ti := textinput.NewModel()
+	ti.Prompt = theme.SearchPrompt.Render(headingPickerPromptText)
+	ti.TextColor = gray
+	ti.BackgroundColor = fuschia
+	ti.CursorColor = yellowGreen
+	ti.CharLimit = 200
+	ti.Focus()
+
+	m := headingPickerModel{
+		input:    ti,
+		headings: headings,
+		theme:    theme,
+	}
+	m.updateMatches()
+	return m
+}
+
+func (m *headingPickerModel) headingTexts() []string {
+	texts := make([]string, len(m.headings))
+	for i, h := range m.headings {
+		texts[i] = h.text
+	}
+	return texts
+}
+
+// updateMatches re-runs the fuzzy search against the current query. With
+// an empty query every heading matches, in document order.
+func (m *headingPickerModel) updateMatches() {
+	query := m.input.Value()
+	if query == "" {
+		m.matches = make(fuzzy.Matches, len(m.headings))
+		for i := range m.headings {
+			m.matches[i] = fuzzy.Match{Str: m.headings[i].text, Index: i}
+		}
+	} else {
+		m.matches = fuzzy.Find(query, m.headingTexts())
+	}
+
+	if m.selected >= len(m.matches) {
+		m.selected = max(0, len(m.matches)-1)
+	}
+}
+
+func (m *headingPickerModel) moveSelection(delta int) {
+	if len(m.matches) == 0 {
+		return
+	}
+	m.selected = ((m.selected+delta)%len(m.matches) + len(m.matches)) % len(m.matches)
+}
+
+func (m *headingPickerModel) selectedHeading() (heading, bool) {
+	if m.selected < 0 || m.selected >= len(m.matches) {
+		return heading{}, false
+	}
+	return m.headings[m.matches[m.selected].Index], true
+}
+
+// headingPickerFooterHeight reports how many lines m's footer (the input
+// line plus its capped result list) takes up, so the caller can reserve
+// that much space below the viewport.
+func headingPickerFooterHeight(m headingPickerModel) int {
+	return 1 + min(len(m.matches), headingPickerMaxResults)
+}
+
+func (m headingPickerModel) view(width int) string {
+	var b strings.Builder
+
+	b.WriteString(textinput.View(m.input))
+	b.WriteString("\n")
+
+	for i, match := range m.matches {
+		if i >= headingPickerMaxResults {
+			break
+		}
+
+		h := m.headings[match.Index]
+		text := highlightRunes(match.Str, match.MatchedIndexes, m.theme.MatchHighlight)
+		line := strings.Repeat("  ", h.level-1) + text
+
+		if i == m.selected {
+			line = m.theme.Note.Render("> ") + line
+		} else {
+			line = "  " + line
+		}
+
+		b.WriteString(truncate(line, width))
+		b.WriteString("\n")
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// highlightRunes wraps the runes of s at the given indexes with style,
+// used to show which characters of a heading matched the fuzzy query.
+func highlightRunes(s string, indexes []int, style lipgloss.Style) string {
+	if len(indexes) == 0 {
+		return s
+	}
+
+	matched := make(map[int]bool, len(indexes))
+	for _, i := range indexes {
+		matched[i] = true
+	}
+
+	var b strings.Builder
+	for i, r := range []rune(s) {
+		if matched[i] {
+			b.WriteString(style.Render(string(r)))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
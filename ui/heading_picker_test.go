@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseHeadings(t *testing.T) {
+	body := strings.Join([]string{
+		"# Title",
+		"",
+		"Some intro text.",
+		"",
+		"## Section One",
+		"",
+		"```",
+		"# this looks like a heading but is inside a fence",
+		"```",
+		"",
+		"### Section One Point One",
+		"",
+		"~~~",
+		"## also fenced, with the other fence style",
+		"~~~",
+		"",
+		"#### Section One Point One Point One",
+		"",
+		"####### Not a heading, too many #s",
+	}, "\n")
+
+	headings := parseHeadings(body)
+
+	want := []heading{
+		{level: 1, text: "Title", line: 0},
+		{level: 2, text: "Section One", line: 4},
+		{level: 3, text: "Section One Point One", line: 10},
+		{level: 4, text: "Section One Point One Point One", line: 16},
+	}
+
+	if len(headings) != len(want) {
+		t.Fatalf("parseHeadings returned %d headings, want %d: %+v", len(headings), len(want), headings)
+	}
+	for i, h := range headings {
+		if h != want[i] {
+			t.Errorf("heading %d = %+v, want %+v", i, h, want[i])
+		}
+	}
+}
+
+func TestParseHeadingsNoHeadings(t *testing.T) {
+	body := "Just a paragraph.\n\nAnd another one, no headings here at all."
+	if headings := parseHeadings(body); len(headings) != 0 {
+		t.Errorf("parseHeadings = %+v, want none", headings)
+	}
+}
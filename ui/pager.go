@@ -5,15 +5,19 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/boba"
 	"github.com/charmbracelet/boba/textinput"
 	"github.com/charmbracelet/boba/viewport"
 	"github.com/charmbracelet/charm"
-	"github.com/charmbracelet/charm/ui/common"
 	"github.com/charmbracelet/glamour"
-	te "github.com/muesli/termenv"
+	"github.com/fsnotify/fsnotify"
 )
 
 const (
@@ -22,26 +26,45 @@ const (
 	gray             = "#333333"
 	yellowGreen      = "#ECFD65"
 	fuschia          = "#EE6FF8"
+	cream            = "#FFFDF5"
+	green            = "#02BA84"
 	noteHeadingText  = " Set Memo "
 	notePromptText   = " > "
-)
-
-var (
-	noteHeading = te.String(noteHeadingText).
-			Foreground(common.Cream.Color()).
-			Background(common.Green.Color()).
-			String()
-
-	statusBarBg          = common.NewColorPair("#242424", "#E6E6E6")
-	statusBarNoteFg      = common.NewColorPair("#7D7D7D", "#656565")
-	statusBarScrollPosFg = common.NewColorPair("#5A5A5A", "#949494")
+	searchPromptText = " / "
+	exportPromptText = " export (prefix with ! to export raw markdown): "
+	flashDuration    = 2 * time.Second
 )
 
 // MSG
 
-type contentRenderedMsg string
+// contentRenderedMsg carries the freshly glamour-rendered document along
+// with the source-line->rendered-line map built for it.
+type contentRenderedMsg struct {
+	content string
+	lineMap []int
+}
+
 type noteSavedMsg *charm.Markdown
 
+// fileChangedMsg is sent when the local markdown file currently on
+// display has been written to, renamed, created or removed on disk.
+type fileChangedMsg struct{}
+
+// exportedMsg is sent once the document has been written to disk via the
+// export prompt.
+type exportedMsg struct {
+	path  string
+	bytes int
+}
+
+// yankedMsg is sent once the document body has been copied to the
+// system clipboard.
+type yankedMsg struct{}
+
+// flashTickMsg drives the expiry of the status bar's transient
+// confirmation message.
+type flashTickMsg struct{}
+
 // MODEL
 
 type pagerState int
@@ -49,38 +72,99 @@ type pagerState int
 const (
 	pagerStateBrowse pagerState = iota
 	pagerStateSetNote
+	pagerStateSearch
+	pagerStateExport
+	pagerStateHeadingPicker
 )
 
+// searchMatch is a single hit for the active search query, expressed as a
+// line offset into the glamour-rendered viewport content.
+type searchMatch struct {
+	renderedLine int
+}
+
 type pagerModel struct {
-	cc           *charm.Client
-	viewport     viewport.Model
-	state        pagerState
-	glamourStyle string
-	width        int
-	height       int
-	textInput    textinput.Model
+	cc            *charm.Client
+	viewport      viewport.Model
+	state         pagerState
+	glamourStyle  string
+	theme         PagerTheme
+	width         int
+	height        int
+	textInput     textinput.Model
+	searchInput   textinput.Model
+	exportInput   textinput.Model
+	headingPicker headingPickerModel
+
+	// flashMessage is a transient confirmation (e.g. "exported to ...")
+	// shown in the status bar in place of the note until flashExpiry.
+	flashMessage string
+	flashExpiry  time.Time
+
+	// Rendered lines for the document currently loaded in the viewport,
+	// kept around so a search can be re-run (and match offsets translated)
+	// without having to re-render with glamour.
+	renderedLines []string
+
+	// lineMap[i] is the rendered-line offset that source line i begins
+	// on, built alongside renderedLines by buildLineMap.
+	lineMap []int
+
+	// Search state. matches is nil when no search is active. lastQuery is
+	// kept so matches can be rebuilt after a glamour re-render (e.g. on
+	// resize), since the rendered line offsets shift.
+	matches    []searchMatch
+	matchIndex int
+	lastQuery  string
 
 	// Current document being rendered, sans-glamour rendering. We cache
 	// this here so we can re-render it on resize.
 	currentDocument *markdown
+
+	// fileWatcher watches currentDocument's path on disk when it's a
+	// local file, so edits made in an external editor show up live.
+	fileWatcher *fsnotify.Watcher
+	watchedPath string
 }
 
 func newPagerModel(glamourStyle string) pagerModel {
+	theme, err := loadPagerTheme()
+	if err != nil {
+		// A malformed theme file shouldn't keep glow from starting; fall
+		// back to the defaults and let the user notice their theme isn't
+		// taking effect.
+		theme = DefaultPagerTheme()
+	}
+
 	ti := textinput.NewModel()
-	ti.Prompt = te.String(notePromptText).
-		Foreground(te.ColorProfile().Color(gray)).
-		Background(te.ColorProfile().Color(yellowGreen)).
-		String()
+	ti.Prompt = theme.NotePrompt.Render(notePromptText)
 	ti.TextColor = gray
 	ti.BackgroundColor = yellowGreen
 	ti.CursorColor = fuschia
 	ti.CharLimit = noteCharacterLimit
 	ti.Focus()
 
+	si := textinput.NewModel()
+	si.Prompt = theme.SearchPrompt.Render(searchPromptText)
+	si.TextColor = gray
+	si.BackgroundColor = fuschia
+	si.CursorColor = yellowGreen
+	si.CharLimit = noteCharacterLimit
+
+	ei := textinput.NewModel()
+	ei.Prompt = theme.NotePrompt.Render(exportPromptText)
+	ei.TextColor = gray
+	ei.BackgroundColor = yellowGreen
+	ei.CursorColor = fuschia
+	ei.CharLimit = 1024
+
 	return pagerModel{
 		state:        pagerStateBrowse,
 		glamourStyle: glamourStyle,
+		theme:        theme,
 		textInput:    ti,
+		searchInput:  si,
+		exportInput:  ei,
 	}
 }
 
@@ -88,11 +172,34 @@ func (m *pagerModel) setSize(w, h int) {
 	m.width = w
 	m.height = h
 	m.viewport.Width = w
-	m.viewport.Height = h - statusBarHeight
+	m.syncViewportHeight()
 	m.textInput.Width = w - len(noteHeadingText) - len(notePromptText) - 1
+	m.searchInput.Width = w - len(searchPromptText) - 1
+	m.exportInput.Width = w - len(exportPromptText) - 1
+}
+
+// footerHeight reports how many lines the footer currently drawn below the
+// viewport takes up. Every footer but the heading picker's is a single
+// line; the picker's grows with its (capped) result count, so the
+// viewport has to shrink to make room or its own content scrolls off
+// screen.
+func (m *pagerModel) footerHeight() int {
+	if m.state == pagerStateHeadingPicker {
+		return headingPickerFooterHeight(m.headingPicker)
+	}
+	return statusBarHeight
+}
+
+// syncViewportHeight re-derives the viewport's height from the current
+// footer size, so the two never overflow the terminal between them. It
+// must be called any time m.state or the heading picker's match count
+// changes.
+func (m *pagerModel) syncViewportHeight() {
+	m.viewport.Height = max(0, m.height-m.footerHeight())
 }
 
 func (m *pagerModel) setContent(s string) {
+	m.renderedLines = strings.Split(s, "\n")
 	m.viewport.SetContent(s)
 }
 
@@ -101,6 +208,286 @@ func (m *pagerModel) unload() {
 	m.viewport.SetContent("")
 	m.viewport.Y = 0
 	m.textInput.Reset()
+	m.searchInput.Reset()
+	m.exportInput.Reset()
+	m.flashMessage = ""
+	m.flashExpiry = time.Time{}
+	m.renderedLines = nil
+	m.lineMap = nil
+	m.matches = nil
+	m.matchIndex = 0
+	m.stopWatching()
+}
+
+// watchLocalFile starts (or, if the path has changed, restarts) an
+// fsnotify watch on path and returns a command that waits for the next
+// relevant filesystem event. If path is already being watched it does
+// nothing and returns nil.
+func (m *pagerModel) watchLocalFile(path string) boba.Cmd {
+	if m.fileWatcher != nil && m.watchedPath == path {
+		return nil
+	}
+	m.stopWatching()
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return func() boba.Msg { return errMsg(err) }
+	}
+	if err := watcher.Add(path); err != nil {
+		watcher.Close()
+		return func() boba.Msg { return errMsg(err) }
+	}
+
+	m.fileWatcher = watcher
+	m.watchedPath = path
+	return m.watchEvents()
+}
+
+// stopWatching tears down the current file watcher, if any.
+func (m *pagerModel) stopWatching() {
+	if m.fileWatcher != nil {
+		m.fileWatcher.Close()
+		m.fileWatcher = nil
+	}
+	m.watchedPath = ""
+}
+
+// watchEvents blocks on the active watcher until a write, rename, create
+// or remove event comes through, then reports it as a fileChangedMsg. It's
+// re-issued after every fileChangedMsg to keep watching for as long as
+// the same local file is on display.
+func (m *pagerModel) watchEvents() boba.Cmd {
+	watcher := m.fileWatcher
+	path := m.watchedPath
+	if watcher == nil {
+		return nil
+	}
+
+	return func() boba.Msg {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return nil
+				}
+				if event.Op&(fsnotify.Rename|fsnotify.Remove) != 0 {
+					// Many editors save by writing to a temp file and
+					// renaming it into place, which some platforms
+					// report as losing the watch on the original path.
+					// Re-arm it on the file at the same path.
+					_ = watcher.Add(path)
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Rename|fsnotify.Create|fsnotify.Remove) != 0 {
+					return fileChangedMsg{}
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return nil
+				}
+				return errMsg(err)
+			}
+		}
+	}
+}
+
+// compileSearchQuery turns a user query into a case-insensitive regexp. If
+// the query isn't valid regex syntax, it's escaped and matched literally,
+// so plain-text searches with stray regex metacharacters (a ".", a "(")
+// still work as a substring search rather than erroring out.
+func compileSearchQuery(query string) *regexp.Regexp {
+	if re, err := regexp.Compile("(?i)" + query); err == nil {
+		return re
+	}
+	return regexp.MustCompile("(?i)" + regexp.QuoteMeta(query))
+}
+
+// runSearch walks the plain-text document body for the given query
+// (case-insensitive, optionally regex), then locates the rendered line
+// that actually contains each hit. sourceLineToRendered gives a good
+// starting guess, but glamour styling can still shift a line by a bit
+// even with the real per-block map, so we search outward from that guess
+// for the nearest rendered line that contains the text (ANSI codes and
+// all). Several source lines can resolve to the same rendered line (e.g.
+// two lines of the same wrapped paragraph, or simply close guesses), so
+// hits are deduped by rendered line and the result sorted top to bottom.
+func (m *pagerModel) runSearch(query string) {
+	m.matches = nil
+	m.matchIndex = 0
+	if query == "" || m.currentDocument == nil {
+		return
+	}
+
+	re := compileSearchQuery(query)
+	bodyLines := strings.Split(m.currentDocument.Body, "\n")
+
+	seen := make(map[int]bool)
+	for i, line := range bodyLines {
+		if !re.MatchString(line) {
+			continue
+		}
+		guess := m.sourceLineToRendered(i)
+		renderedLine, ok := m.findRenderedLine(re, guess)
+		if !ok || seen[renderedLine] {
+			continue
+		}
+		seen[renderedLine] = true
+		m.matches = append(m.matches, searchMatch{renderedLine: renderedLine})
+	}
+
+	sort.Slice(m.matches, func(a, b int) bool {
+		return m.matches[a].renderedLine < m.matches[b].renderedLine
+	})
+}
+
+// findRenderedLine searches m.renderedLines for a line matching re
+// (ANSI-aware), starting at near and expanding outward so the nearest
+// actual occurrence wins over a merely plausible guess.
+func (m *pagerModel) findRenderedLine(re *regexp.Regexp, near int) (int, bool) {
+	n := len(m.renderedLines)
+	if n == 0 {
+		return 0, false
+	}
+	if near < 0 {
+		near = 0
+	}
+	if near >= n {
+		near = n - 1
+	}
+
+	for d := 0; d < n; d++ {
+		if i := near - d; i >= 0 && ansiMatch(m.renderedLines[i], re) {
+			return i, true
+		}
+		if i := near + d; d > 0 && i < n && ansiMatch(m.renderedLines[i], re) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// sourceLineToRendered translates a line number in the raw markdown body
+// into the rendered-line offset it landed on, per the map built in
+// buildLineMap for the document currently on screen.
+func (m *pagerModel) sourceLineToRendered(src int) int {
+	if src < 0 || len(m.lineMap) == 0 {
+		return 0
+	}
+	if src >= len(m.lineMap) {
+		src = len(m.lineMap) - 1
+	}
+	return m.lineMap[src]
+}
+
+// applyHighlights re-renders the viewport content with the active matches
+// wrapped in a reverse-video style. It leaves m.renderedLines untouched so
+// the plain rendering is always available to re-derive from.
+func (m *pagerModel) applyHighlights() {
+	if len(m.matches) == 0 || m.lastQuery == "" {
+		m.viewport.SetContent(strings.Join(m.renderedLines, "\n"))
+		return
+	}
+
+	re := compileSearchQuery(m.lastQuery)
+	lines := make([]string, len(m.renderedLines))
+	copy(lines, m.renderedLines)
+	for _, match := range m.matches {
+		if match.renderedLine >= 0 && match.renderedLine < len(lines) {
+			if highlighted, ok := m.highlightMatches(lines[match.renderedLine], re); ok {
+				lines[match.renderedLine] = highlighted
+			}
+		}
+	}
+	m.viewport.SetContent(strings.Join(lines, "\n"))
+}
+
+// ansiRe matches a single termenv/ANSI SGR escape sequence.
+var ansiRe = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// stripANSI removes ANSI escape sequences from s, returning the plain
+// text alongside a map from each plain-text rune index to the byte
+// offset it came from in s, so a match found in the plain text can be
+// translated back to a position in the original, styled string.
+func stripANSI(s string) (plain string, byteOffsets []int) {
+	var b strings.Builder
+	i := 0
+	for i < len(s) {
+		if loc := ansiRe.FindStringIndex(s[i:]); loc != nil && loc[0] == 0 {
+			i += loc[1]
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		byteOffsets = append(byteOffsets, i)
+		b.WriteRune(r)
+		i += size
+	}
+	return b.String(), byteOffsets
+}
+
+// ansiMatch reports whether line matches re, ignoring any ANSI escape
+// sequences embedded in line.
+func ansiMatch(line string, re *regexp.Regexp) bool {
+	plain, _ := stripANSI(line)
+	return re.MatchString(plain)
+}
+
+// highlightMatches wraps every non-overlapping occurrence of re in line
+// with the theme's match-highlight style, skipping over any ANSI escape
+// sequences glamour styled the line with.
+func (m *pagerModel) highlightMatches(line string, re *regexp.Regexp) (string, bool) {
+	plain, byteOffsets := stripANSI(line)
+	if plain == "" {
+		return line, false
+	}
+
+	locs := re.FindAllStringIndex(plain, -1)
+	if len(locs) == 0 {
+		return line, false
+	}
+
+	var b strings.Builder
+	prevEnd := 0
+	for _, loc := range locs {
+		startRune := utf8.RuneCountInString(plain[:loc[0]])
+		endRune := utf8.RuneCountInString(plain[:loc[1]])
+
+		start := byteOffsets[startRune]
+		end := len(line)
+		if endRune < len(byteOffsets) {
+			end = byteOffsets[endRune]
+		}
+
+		b.WriteString(line[prevEnd:start])
+		b.WriteString(m.theme.MatchHighlight.Render(line[start:end]))
+		prevEnd = end
+	}
+	b.WriteString(line[prevEnd:])
+
+	return b.String(), true
+}
+
+// gotoMatch centers the viewport on the current match, when possible.
+func (m *pagerModel) gotoMatch() {
+	if len(m.matches) == 0 {
+		return
+	}
+	m.scrollToRenderedLine(m.matches[m.matchIndex].renderedLine)
+}
+
+// scrollToRenderedLine centers the viewport on the given rendered line,
+// clamped to the document's bounds.
+func (m *pagerModel) scrollToRenderedLine(line int) {
+	offset := line - m.viewport.Height/2
+	if offset < 0 {
+		offset = 0
+	}
+	maxOffset := len(m.renderedLines) - m.viewport.Height
+	if maxOffset < 0 {
+		maxOffset = 0
+	}
+	if offset > maxOffset {
+		offset = maxOffset
+	}
+	m.viewport.Y = offset
 }
 
 // UPDATE
@@ -132,6 +519,62 @@ func pagerUpdate(msg boba.Msg, m pagerModel) (pagerModel, boba.Cmd) {
 				m.textInput.Reset()
 				return m, cmd
 			}
+		case pagerStateSearch:
+			switch msg.String() {
+			case "esc":
+				m.state = pagerStateBrowse
+				m.searchInput.Reset()
+				return m, nil
+			case "enter":
+				m.lastQuery = m.searchInput.Value()
+				m.runSearch(m.lastQuery)
+				m.applyHighlights()
+				m.gotoMatch()
+				m.state = pagerStateBrowse
+				return m, nil
+			}
+		case pagerStateExport:
+			switch msg.String() {
+			case "esc":
+				m.state = pagerStateBrowse
+				m.exportInput.Reset()
+				return m, nil
+			case "enter":
+				value := m.exportInput.Value()
+				raw := strings.HasPrefix(value, "!")
+				path := strings.TrimPrefix(value, "!")
+				m.state = pagerStateBrowse
+				m.exportInput.Reset()
+				if path == "" {
+					return m, nil
+				}
+				return m, exportDocument(m, path, raw)
+			}
+		case pagerStateHeadingPicker:
+			switch msg.String() {
+			case "esc":
+				m.state = pagerStateBrowse
+				m.syncViewportHeight()
+				return m, nil
+			case "up":
+				fallthrough
+			case "ctrl+p":
+				m.headingPicker.moveSelection(-1)
+				return m, nil
+			case "down":
+				fallthrough
+			case "ctrl+n":
+				m.headingPicker.moveSelection(1)
+				return m, nil
+			case "enter":
+				h, ok := m.headingPicker.selectedHeading()
+				m.state = pagerStateBrowse
+				m.syncViewportHeight()
+				if ok {
+					m.scrollToRenderedLine(m.sourceLineToRendered(h.line))
+				}
+				return m, nil
+			}
 		default:
 			switch msg.String() {
 			case "q":
@@ -141,7 +584,35 @@ func pagerUpdate(msg boba.Msg, m pagerModel) (pagerModel, boba.Cmd) {
 					m.state = pagerStateBrowse
 					return m, nil
 				}
+				if len(m.matches) > 0 {
+					m.matches = nil
+					m.lastQuery = ""
+					m.viewport.SetContent(strings.Join(m.renderedLines, "\n"))
+				}
+			case "/":
+				m.state = pagerStateSearch
+				m.searchInput.Reset()
+				return m, textinput.Blink(m.searchInput)
+			case "e":
+				m.state = pagerStateExport
+				m.exportInput.Reset()
+				return m, textinput.Blink(m.exportInput)
+			case "y":
+				return m, yankDocument(m.currentDocument.Body)
+			case ":":
+				m.state = pagerStateHeadingPicker
+				m.headingPicker = newHeadingPickerModel(m.theme, parseHeadings(m.currentDocument.Body))
+				m.syncViewportHeight()
+				return m, textinput.Blink(m.headingPicker.input)
 			case "n":
+				// Once a search is active, n/N step through matches.
+				// Otherwise n sets the memo, as it always has.
+				if len(m.matches) > 0 {
+					m.matchIndex = (m.matchIndex + 1) % len(m.matches)
+					m.gotoMatch()
+					return m, nil
+				}
+
 				// Users can't set the note on news markdown
 				if m.currentDocument.markdownType == newsMarkdown {
 					break
@@ -154,14 +625,67 @@ func pagerUpdate(msg boba.Msg, m pagerModel) (pagerModel, boba.Cmd) {
 					m.textInput.CursorEnd()
 				}
 				return m, textinput.Blink(m.textInput)
+			case "N":
+				if len(m.matches) > 0 {
+					m.matchIndex--
+					if m.matchIndex < 0 {
+						m.matchIndex = len(m.matches) - 1
+					}
+					m.gotoMatch()
+				}
+				return m, nil
 			}
 		}
 
 	// Glow has rendered the content
 	case contentRenderedMsg:
-		m.setContent(string(msg))
+		prevY := m.viewport.Y
+		m.setContent(msg.content)
+		m.lineMap = msg.lineMap
+		maxY := max(0, len(m.renderedLines)-m.viewport.Height)
+		m.viewport.Y = min(prevY, maxY)
+
+		if m.lastQuery != "" {
+			m.runSearch(m.lastQuery)
+			m.applyHighlights()
+		}
+
+		var cmd boba.Cmd
+		if m.currentDocument != nil && m.currentDocument.markdownType == localMarkdown {
+			cmd = m.watchLocalFile(m.currentDocument.localPath)
+		}
+		return m, cmd
+
+	case exportedMsg:
+		m.flashMessage = fmt.Sprintf("Exported %d bytes to %s", msg.bytes, msg.path)
+		m.flashExpiry = time.Now().Add(flashDuration)
+		return m, flashExpireCmd(flashDuration)
+
+	case yankedMsg:
+		m.flashMessage = "Copied to clipboard"
+		m.flashExpiry = time.Now().Add(flashDuration)
+		return m, flashExpireCmd(flashDuration)
+
+	case flashTickMsg:
+		if !m.flashExpiry.IsZero() && !time.Now().Before(m.flashExpiry) {
+			m.flashMessage = ""
+			m.flashExpiry = time.Time{}
+		}
 		return m, nil
 
+	// The file behind currentDocument changed on disk; reload it and
+	// keep watching.
+	case fileChangedMsg:
+		if m.currentDocument == nil || m.currentDocument.markdownType != localMarkdown {
+			return m, nil
+		}
+		b, err := os.ReadFile(m.currentDocument.localPath)
+		if err != nil {
+			return m, func() boba.Msg { return errMsg(err) }
+		}
+		m.currentDocument.Body = string(b)
+		return m, boba.Batch(renderWithGlamour(m, m.currentDocument.Body), m.watchEvents())
+
 	// We've reveived terminal dimensions, either for the first time or
 	// after a resize
 	case terminalSizeMsg:
@@ -181,6 +705,17 @@ func pagerUpdate(msg boba.Msg, m pagerModel) (pagerModel, boba.Cmd) {
 	case pagerStateSetNote:
 		m.textInput, cmd = textinput.Update(msg, m.textInput)
 		cmds = append(cmds, cmd)
+	case pagerStateSearch:
+		m.searchInput, cmd = textinput.Update(msg, m.searchInput)
+		cmds = append(cmds, cmd)
+	case pagerStateExport:
+		m.exportInput, cmd = textinput.Update(msg, m.exportInput)
+		cmds = append(cmds, cmd)
+	case pagerStateHeadingPicker:
+		m.headingPicker.input, cmd = textinput.Update(msg, m.headingPicker.input)
+		m.headingPicker.updateMatches()
+		m.syncViewportHeight()
+		cmds = append(cmds, cmd)
 	default:
 		m.viewport, cmd = viewport.Update(msg, m.viewport)
 		cmds = append(cmds, cmd)
@@ -193,9 +728,16 @@ func pagerUpdate(msg boba.Msg, m pagerModel) (pagerModel, boba.Cmd) {
 
 func pagerView(m pagerModel) string {
 	var footer string
-	if m.state == pagerStateSetNote {
+	switch m.state {
+	case pagerStateSetNote:
 		footer = pagerSetNoteView(m)
-	} else {
+	case pagerStateSearch:
+		footer = pagerSearchView(m)
+	case pagerStateExport:
+		footer = pagerExportView(m)
+	case pagerStateHeadingPicker:
+		footer = m.headingPicker.view(m.width)
+	default:
 		footer = pagerStatusBarView(m)
 	}
 
@@ -209,50 +751,97 @@ func pagerView(m pagerModel) string {
 func pagerStatusBarView(m pagerModel) string {
 	// Logo
 	logoText := " Glow "
-	logo := glowLogoView(logoText)
+	logo := m.theme.Logo.Render(logoText)
 
 	// Scroll percent
 	scrollPercent := math.Max(0.0, math.Min(1.0, m.viewport.ScrollPercent()))
 	percentText := fmt.Sprintf(" %3.f%% ", scrollPercent*100)
-	percent := te.String(percentText).
-		Foreground(statusBarScrollPosFg.Color()).
-		Background(statusBarBg.Color()).
-		String()
+	percent := m.theme.ScrollPercent.Render(percentText)
+
+	// Match position, if a search is active
+	var matchText string
+	if len(m.matches) > 0 {
+		matchText = fmt.Sprintf(" match %d/%d ", m.matchIndex+1, len(m.matches))
+	}
+	match := m.theme.Note.Render(matchText)
 
-	// Note
+	// Note, or a transient flash message (e.g. "exported to ...") in its place
 	noteText := m.currentDocument.Note
 	if len(noteText) == 0 {
 		noteText = "(No title)"
 	}
-	noteText = truncate(" "+noteText+" ", max(0, m.width-len(logoText)-len(percentText)))
-	note := te.String(noteText).
-		Foreground(statusBarNoteFg.Color()).
-		Background(statusBarBg.Color()).String()
+	if m.flashMessage != "" {
+		noteText = m.flashMessage
+	}
+	noteText = truncate(" "+noteText+" ", max(0, m.width-len(logoText)-len(percentText)-len(matchText)))
+	note := m.theme.Note.Render(noteText)
 
 	// Empty space
-	emptyCell := te.String(" ").Background(statusBarBg.Color()).String()
-	padding := max(0, m.width-len(logoText)-len(noteText)-len(percentText))
+	emptyCell := m.theme.StatusBarBg.Render(" ")
+	padding := max(0, m.width-len(logoText)-len(noteText)-len(percentText)-len(matchText))
 	emptySpace := strings.Repeat(emptyCell, padding)
 
-	return logo + note + emptySpace + percent
+	return logo + note + emptySpace + match + percent
 }
 
 func pagerSetNoteView(m pagerModel) string {
-	return noteHeading + textinput.View(m.textInput)
+	return m.theme.NoteHeading.Render(noteHeadingText) + textinput.View(m.textInput)
+}
+
+func pagerSearchView(m pagerModel) string {
+	return textinput.View(m.searchInput)
+}
+
+func pagerExportView(m pagerModel) string {
+	return m.theme.NoteHeading.Render(" Export ") + textinput.View(m.exportInput)
 }
 
 // CMD
 
 func renderWithGlamour(m pagerModel, md string) boba.Cmd {
 	return func() boba.Msg {
-		s, err := glamourRender(m, md)
+		s, lineMap, err := glamourRender(m, md)
 		if err != nil {
 			return errMsg(err)
 		}
-		return contentRenderedMsg(s)
+		return contentRenderedMsg{content: s, lineMap: lineMap}
+	}
+}
+
+// exportDocument writes the current document to path, either its raw
+// markdown body (raw) or the glamour-rendered ANSI currently on screen.
+func exportDocument(m pagerModel, path string, raw bool) boba.Cmd {
+	return func() boba.Msg {
+		content := strings.Join(m.renderedLines, "\n")
+		if raw {
+			content = m.currentDocument.Body
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			return errMsg(err)
+		}
+		return exportedMsg{path: path, bytes: len(content)}
 	}
 }
 
+// yankDocument copies the document's raw markdown body to the system
+// clipboard.
+func yankDocument(body string) boba.Cmd {
+	return func() boba.Msg {
+		if err := clipboard.WriteAll(body); err != nil {
+			return errMsg(err)
+		}
+		return yankedMsg{}
+	}
+}
+
+// flashExpireCmd schedules the status bar's transient confirmation
+// message to be checked for expiry after d.
+func flashExpireCmd(d time.Duration) boba.Cmd {
+	return boba.Tick(d, func(time.Time) boba.Msg {
+		return flashTickMsg{}
+	})
+}
+
 func saveDocumentNote(cc *charm.Client, id int, note string) boba.Cmd {
 	if cc == nil {
 		return func() boba.Msg {
@@ -268,13 +857,27 @@ func saveDocumentNote(cc *charm.Client, id int, note string) boba.Cmd {
 }
 
 // This is where the magic happens
-func glamourRender(m pagerModel, markdown string) (string, error) {
-
+func glamourRender(m pagerModel, markdown string) (string, []int, error) {
 	if os.Getenv("GLOW_DISABLE_GLAMOUR") != "" {
-		return markdown, nil
+		return markdown, identityLineMap(markdown), nil
+	}
+
+	r, err := newGlamourRenderer(m)
+	if err != nil {
+		return "", nil, err
 	}
 
-	// initialize glamour
+	content, err := renderAndTrim(r, markdown)
+	if err != nil {
+		return "", nil, err
+	}
+
+	lineMap := buildLineMap(r, markdown)
+
+	return content, lineMap, nil
+}
+
+func newGlamourRenderer(m pagerModel) (*glamour.TermRenderer, error) {
 	var gs glamour.TermRendererOption
 	if m.glamourStyle == "auto" {
 		gs = glamour.WithAutoStyle()
@@ -283,21 +886,21 @@ func glamourRender(m pagerModel, markdown string) (string, error) {
 	}
 
 	width := max(0, min(maxDocumentWidth, m.viewport.Width))
-	r, err := glamour.NewTermRenderer(
+	return glamour.NewTermRenderer(
 		gs,
 		glamour.WithWordWrap(width),
 	)
-	if err != nil {
-		return "", err
-	}
+}
 
+// renderAndTrim renders markdown with r and trims trailing whitespace off
+// each output line, the way glow has always displayed rendered documents.
+func renderAndTrim(r *glamour.TermRenderer, markdown string) (string, error) {
 	out, err := r.Render(markdown)
 	if err != nil {
 		return "", err
 	}
 
-	// trim lines
-	lines := strings.Split(string(out), "\n")
+	lines := strings.Split(out, "\n")
 
 	var content string
 	for i, s := range lines {
@@ -311,3 +914,92 @@ func glamourRender(m pagerModel, markdown string) (string, error) {
 
 	return content, nil
 }
+
+// identityLineMap is used when glamour rendering is disabled, so the
+// source and "rendered" text are the same and every line maps to itself.
+func identityLineMap(markdown string) []int {
+	n := len(strings.Split(markdown, "\n"))
+	lineMap := make([]int, n)
+	for i := range lineMap {
+		lineMap[i] = i
+	}
+	return lineMap
+}
+
+// blockMargin measures the leading/trailing blank lines glamour's document
+// style adds around any standalone render, by rendering a single throwaway
+// line through r and counting everything past that one content line. Every
+// per-block render in buildLineMap picks up this same margin, so it has to
+// be subtracted back out or the accumulated offset overshoots by roughly
+// one margin's worth per block.
+func blockMargin(r *glamour.TermRenderer) int {
+	rendered, err := renderAndTrim(r, "x")
+	if err != nil {
+		return 0
+	}
+	return max(0, len(strings.Split(rendered, "\n"))-1)
+}
+
+// buildLineMap renders the document one blank-line-delimited block at a
+// time with the same renderer used for the full document, and records the
+// rendered-line offset each source line lands on. This accumulates the
+// real per-block rendered-line counts (word wrap, styling, etc. all
+// affect them) rather than assuming the document reflows uniformly, so a
+// heading or search hit several blocks into a code-fence-heavy document
+// still lands on the right line. Lines within a block are interpolated
+// between the block's start and end offsets, since rendering every single
+// line standalone would both be too slow and lose word-wrap context. Each
+// standalone block render carries glamour's own document margin, which
+// blockMargin measures once so it can be subtracted back out of every
+// block instead of compounding into the running total.
+func buildLineMap(r *glamour.TermRenderer, body string) []int {
+	margin := blockMargin(r)
+	bodyLines := strings.Split(body, "\n")
+	lineMap := make([]int, len(bodyLines))
+
+	type block struct{ start, end int } // inclusive source line range
+	var blocks []block
+	for i := 0; i < len(bodyLines); {
+		if strings.TrimSpace(bodyLines[i]) == "" {
+			i++
+			continue
+		}
+		start := i
+		for i < len(bodyLines) && strings.TrimSpace(bodyLines[i]) != "" {
+			i++
+		}
+		blocks = append(blocks, block{start: start, end: i - 1})
+	}
+
+	cursor := 0
+	prevEnd := 0
+	for _, blk := range blocks {
+		// Blank lines before this block map to wherever the block starts.
+		for l := prevEnd; l < blk.start; l++ {
+			lineMap[l] = cursor
+		}
+
+		text := strings.Join(bodyLines[blk.start:blk.end+1], "\n")
+		blockLines := 1
+		if rendered, err := renderAndTrim(r, text); err == nil {
+			blockLines = max(1, len(strings.Split(rendered, "\n"))-margin)
+		}
+
+		numSourceLines := blk.end - blk.start + 1
+		for l := blk.start; l <= blk.end; l++ {
+			if numSourceLines > 1 {
+				lineMap[l] = cursor + (l-blk.start)*max(0, blockLines-1)/(numSourceLines-1)
+			} else {
+				lineMap[l] = cursor
+			}
+		}
+
+		cursor += blockLines
+		prevEnd = blk.end + 1
+	}
+	for l := prevEnd; l < len(bodyLines); l++ {
+		lineMap[l] = cursor
+	}
+
+	return lineMap
+}
@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/glamour"
+)
+
+// TestBuildLineMapStaysInBounds is a regression test for an overshoot bug:
+// buildLineMap rendered each block standalone, which picks up glamour's
+// document margin on every block, so the accumulated offset ran past the
+// end of the actual rendered document on any multi-block input.
+func TestBuildLineMapStaysInBounds(t *testing.T) {
+	body := strings.Join([]string{
+		"# Heading One",
+		"",
+		"Some paragraph text under the first heading.",
+		"",
+		"## Heading Two",
+		"",
+		"More paragraph text, a second block, under the second heading.",
+		"",
+		"### Heading Three",
+		"",
+		"A third and final block of text.",
+	}, "\n")
+
+	r, err := glamour.NewTermRenderer(glamour.WithStandardStyle("notty"), glamour.WithWordWrap(80))
+	if err != nil {
+		t.Fatalf("NewTermRenderer: %v", err)
+	}
+
+	rendered, err := renderAndTrim(r, body)
+	if err != nil {
+		t.Fatalf("renderAndTrim: %v", err)
+	}
+	renderedLines := strings.Split(rendered, "\n")
+
+	lineMap := buildLineMap(r, body)
+	if len(lineMap) != len(strings.Split(body, "\n")) {
+		t.Fatalf("buildLineMap returned %d entries, want %d", len(lineMap), len(strings.Split(body, "\n")))
+	}
+
+	for i, l := range lineMap {
+		if l < 0 || l >= len(renderedLines) {
+			t.Errorf("lineMap[%d] = %d, out of bounds for %d rendered lines", i, l, len(renderedLines))
+		}
+	}
+	for i := 1; i < len(lineMap); i++ {
+		if lineMap[i] < lineMap[i-1] {
+			t.Errorf("lineMap is not monotonic at %d: %d < %d", i, lineMap[i], lineMap[i-1])
+		}
+	}
+
+	// Each heading's mapped rendered line should actually contain that
+	// heading's text, stripped of the ANSI styling glamour applied to it.
+	for _, h := range parseHeadings(body) {
+		rl := lineMap[h.line]
+		plain, _ := stripANSI(renderedLines[rl])
+		if !strings.Contains(plain, h.text) {
+			t.Errorf("heading %q mapped to rendered line %d (%q), which doesn't contain it", h.text, rl, plain)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStripANSI(t *testing.T) {
+	const reset = "\x1b[0m"
+	const bold = "\x1b[1m"
+	styled := bold + "hello" + reset + " world"
+
+	plain, offsets := stripANSI(styled)
+	if plain != "hello world" {
+		t.Fatalf("stripANSI plain = %q, want %q", plain, "hello world")
+	}
+	if len(offsets) != len(plain) {
+		t.Fatalf("stripANSI returned %d byte offsets for %d runes", len(offsets), len(plain))
+	}
+
+	// offsets[0] should point at the 'h' in styled, past the leading bold code.
+	if got, want := offsets[0], len(bold); got != want {
+		t.Errorf("offsets[0] = %d, want %d", got, want)
+	}
+	// offsets for "world" should land after the reset code too.
+	wIdx := strings.Index(plain, "world")
+	if got, want := offsets[wIdx], len(bold)+len("hello")+len(reset)+1; got != want {
+		t.Errorf("offsets[%d] = %d, want %d", wIdx, got, want)
+	}
+}
+
+func TestAnsiMatch(t *testing.T) {
+	line := "\x1b[1mhello\x1b[0m world"
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"hello", true},
+		{"hello world", true},
+		{"HELLO", true}, // case-insensitive
+		{"goodbye", false},
+		{"^hello", true}, // regex anchors operate on the stripped plain text
+		{"^world", false},
+	}
+
+	for _, tt := range tests {
+		re := compileSearchQuery(tt.query)
+		if got := ansiMatch(line, re); got != tt.want {
+			t.Errorf("ansiMatch(%q, %q) = %v, want %v", line, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestCompileSearchQueryFallsBackToLiteral(t *testing.T) {
+	// "(" is invalid regex syntax on its own; it should be matched literally
+	// rather than failing to compile.
+	re := compileSearchQuery("(foo")
+	if !re.MatchString("a (foo b") {
+		t.Errorf("expected literal fallback to match %q", "(foo")
+	}
+	if re.MatchString("a foo b") {
+		t.Errorf("literal fallback should not match without the paren")
+	}
+}
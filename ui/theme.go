@@ -0,0 +1,146 @@
+package ui
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// PagerTheme holds the lipgloss styles used to draw the pager's chrome:
+// the status bar, the set-memo prompt and the search prompt. Unlike
+// glamour's document styles, these affect the UI glow draws around the
+// document; the one exception is MatchHighlight, which is applied to
+// search hits inside the rendered document.
+type PagerTheme struct {
+	Logo           lipgloss.Style
+	Note           lipgloss.Style
+	ScrollPercent  lipgloss.Style
+	StatusBarBg    lipgloss.Style
+	NotePrompt     lipgloss.Style
+	NoteHeading    lipgloss.Style
+	SearchPrompt   lipgloss.Style
+	MatchHighlight lipgloss.Style
+}
+
+// DefaultPagerTheme returns the theme matching glow's original, hardcoded
+// termenv colors.
+func DefaultPagerTheme() PagerTheme {
+	statusBarBg := lipgloss.AdaptiveColor{Dark: "#242424", Light: "#E6E6E6"}
+
+	return PagerTheme{
+		Logo: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color(cream)).
+			Background(lipgloss.Color(fuschia)),
+		Note: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Dark: "#7D7D7D", Light: "#656565"}).
+			Background(statusBarBg),
+		ScrollPercent: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Dark: "#5A5A5A", Light: "#949494"}).
+			Background(statusBarBg),
+		StatusBarBg: lipgloss.NewStyle().
+			Background(statusBarBg),
+		NotePrompt: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(gray)).
+			Background(lipgloss.Color(yellowGreen)),
+		NoteHeading: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(cream)).
+			Background(lipgloss.Color(green)),
+		SearchPrompt: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(gray)).
+			Background(lipgloss.Color(fuschia)),
+		MatchHighlight: lipgloss.NewStyle().
+			Reverse(true),
+	}
+}
+
+// themeStyle mirrors PagerTheme's fields as plain hex strings so it can
+// round-trip through JSON; lipgloss.Style itself has no exported fields.
+type themeStyle struct {
+	Foreground string `json:"foreground,omitempty"`
+	Background string `json:"background,omitempty"`
+	Bold       bool   `json:"bold,omitempty"`
+	Reverse    bool   `json:"reverse,omitempty"`
+}
+
+type themeFile struct {
+	Logo           *themeStyle `json:"logo,omitempty"`
+	Note           *themeStyle `json:"note,omitempty"`
+	ScrollPercent  *themeStyle `json:"scrollPct,omitempty"`
+	StatusBarBg    *themeStyle `json:"statusBarBg,omitempty"`
+	NotePrompt     *themeStyle `json:"notePrompt,omitempty"`
+	NoteHeading    *themeStyle `json:"noteHeading,omitempty"`
+	SearchPrompt   *themeStyle `json:"searchPrompt,omitempty"`
+	MatchHighlight *themeStyle `json:"matchHighlight,omitempty"`
+}
+
+func (s *themeStyle) apply(style lipgloss.Style) lipgloss.Style {
+	if s == nil {
+		return style
+	}
+	if s.Foreground != "" {
+		style = style.Foreground(lipgloss.Color(s.Foreground))
+	}
+	if s.Background != "" {
+		style = style.Background(lipgloss.Color(s.Background))
+	}
+	if s.Bold {
+		style = style.Bold(true)
+	}
+	if s.Reverse {
+		style = style.Reverse(true)
+	}
+	return style
+}
+
+// loadPagerTheme builds the default theme and, if a theme.json can be
+// found at $XDG_CONFIG_HOME/glow/theme.json (~/.config/glow/theme.json when
+// XDG_CONFIG_HOME is unset), overlays the fields it sets. A missing file
+// is not an error; any other read error or a malformed theme file is
+// reported.
+func loadPagerTheme() (PagerTheme, error) {
+	theme := DefaultPagerTheme()
+
+	path := defaultThemePath()
+	if path == "" {
+		return theme, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return theme, nil
+		}
+		return theme, err
+	}
+
+	var tf themeFile
+	if err := json.Unmarshal(b, &tf); err != nil {
+		return theme, err
+	}
+
+	theme.Logo = tf.Logo.apply(theme.Logo)
+	theme.Note = tf.Note.apply(theme.Note)
+	theme.ScrollPercent = tf.ScrollPercent.apply(theme.ScrollPercent)
+	theme.StatusBarBg = tf.StatusBarBg.apply(theme.StatusBarBg)
+	theme.NotePrompt = tf.NotePrompt.apply(theme.NotePrompt)
+	theme.NoteHeading = tf.NoteHeading.apply(theme.NoteHeading)
+	theme.SearchPrompt = tf.SearchPrompt.apply(theme.SearchPrompt)
+	theme.MatchHighlight = tf.MatchHighlight.apply(theme.MatchHighlight)
+
+	return theme, nil
+}
+
+func defaultThemePath() string {
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		configDir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configDir, "glow", "theme.json")
+}